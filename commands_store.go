@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kevinschoon/pomo/store"
+)
+
+// RunHistory prints every persisted task, most recent first. It backs
+// the `pomo history` subcommand.
+func RunHistory(w io.Writer, s *store.Store, filter store.TaskFilter) error {
+	tasks, err := s.ListTasks(filter)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		fmt.Fprintf(w, "#%d %s [%d/%d pomodoros, %s]\n",
+			t.ID, t.Message, len(t.Pomodoros), t.NPomodoros, t.Duration)
+	}
+	return nil
+}
+
+// RunStats prints per-tag totals and the current streak for pomodoros
+// completed since `since`. It backs `pomo stats --since 7d`.
+func RunStats(w io.Writer, s *store.Store, since time.Time) error {
+	summary, err := s.TagSummary(since)
+	if err != nil {
+		return err
+	}
+	for _, tag := range summary {
+		fmt.Fprintf(w, "%-20s %4d pomodoros  %s\n", tag.Tag, tag.Count, tag.Duration)
+	}
+	streak, err := s.StreakDays()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "streak: %d day(s)\n", streak)
+	return nil
+}
+
+// RunExport writes every persisted task to w as either csv or json.
+// It backs `pomo export --format csv|json`.
+func RunExport(w io.Writer, s *store.Store, format string) error {
+	tasks, err := s.ListTasks(store.TaskFilter{})
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tasks)
+	case "csv":
+		cw := csv.NewWriter(w)
+		defer cw.Flush()
+		if err := cw.Write([]string{"id", "message", "tags", "n_pomodoros", "duration"}); err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			if err := cw.Write([]string{
+				strconv.Itoa(t.ID),
+				t.Message,
+				strings.Join(t.Tags, ";"),
+				strconv.Itoa(t.NPomodoros),
+				t.Duration.String(),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown export format: %s", format)
+	}
+}
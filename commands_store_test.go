@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kevinschoon/pomo/store"
+)
+
+func TestRunExportCSVJoinsTags(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "pomo.db"))
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	defer s.Close()
+
+	if _, err := s.SaveTask(store.Task{Message: "write report", Tags: []string{"work", "urgent"}, NPomodoros: 1}); err != nil {
+		t.Fatalf("SaveTask: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := RunExport(&buf, s, "csv"); err != nil {
+		t.Fatalf("RunExport: %s", err)
+	}
+	if !strings.Contains(buf.String(), "work;urgent") {
+		t.Fatalf("expected semicolon-joined tags in csv output, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configReloadDebounce coalesces the burst of write events many
+// editors and atomic-save tools generate for a single save.
+const configReloadDebounce = 200 * time.Millisecond
+
+// ConfigWatcher holds the active Config and swaps it in place when the
+// backing file changes, so a running display or notifier stack can
+// pick up new settings without restarting the pomodoro in progress.
+type ConfigWatcher struct {
+	mu       sync.RWMutex
+	current  *Config
+	path     string
+	watcher  *fsnotify.Watcher
+	onChange func(*Config)
+	debounce *time.Timer
+}
+
+// WatchConfig loads path and watches its parent directory for writes,
+// re-parsing and atomically swapping the active Config on change.
+// onChange, if non-nil, is called with the new Config after each
+// successful reload. A config that fails to parse is logged and the
+// previous one stays live.
+func WatchConfig(path string, onChange func(*Config)) (*ConfigWatcher, error) {
+	cfg, err := NewConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	cw := &ConfigWatcher{
+		current:  cfg,
+		path:     path,
+		watcher:  watcher,
+		onChange: onChange,
+	}
+	go cw.run()
+	return cw, nil
+}
+
+// Config returns the currently active configuration.
+func (cw *ConfigWatcher) Config() *Config {
+	cw.mu.RLock()
+	defer cw.mu.RUnlock()
+	return cw.current
+}
+
+// Close stops watching for changes.
+func (cw *ConfigWatcher) Close() error {
+	return cw.watcher.Close()
+}
+
+func (cw *ConfigWatcher) run() {
+	for {
+		select {
+		case event, ok := <-cw.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(cw.path) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if cw.debounce != nil {
+				cw.debounce.Stop()
+			}
+			cw.debounce = time.AfterFunc(configReloadDebounce, cw.reload)
+		case err, ok := <-cw.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("config watcher: %s", err)
+		}
+	}
+}
+
+func (cw *ConfigWatcher) reload() {
+	cfg, err := NewConfig(cw.path)
+	if err != nil {
+		log.Printf("config watcher: keeping previous config, reload of %s failed: %s", cw.path, err)
+		return
+	}
+	cw.mu.Lock()
+	cw.current = cfg
+	cw.mu.Unlock()
+	if cw.onChange != nil {
+		cw.onChange(cfg)
+	}
+}
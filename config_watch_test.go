@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchConfigReloadsOnWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"colors":{}}`), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	changed := make(chan *Config, 1)
+	cw, err := WatchConfig(path, func(cfg *Config) { changed <- cfg })
+	if err != nil {
+		t.Fatalf("WatchConfig: %s", err)
+	}
+	defer cw.Close()
+
+	if err := ioutil.WriteFile(path, []byte(`{"colors":{"running":"green"}}`), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	select {
+	case cfg := <-changed:
+		if _, ok := cfg.Colors["running"]; !ok {
+			t.Fatalf("expected reloaded config to have a running color, got %+v", cfg.Colors)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for config reload")
+	}
+
+	if _, ok := cw.Config().Colors["running"]; !ok {
+		t.Fatal("ConfigWatcher.Config() did not reflect the reload")
+	}
+}
+
+func TestWatchConfigKeepsPreviousOnBadReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"colors":{"running":"green"}}`), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	changed := make(chan *Config, 1)
+	cw, err := WatchConfig(path, func(cfg *Config) { changed <- cfg })
+	if err != nil {
+		t.Fatalf("WatchConfig: %s", err)
+	}
+	defer cw.Close()
+
+	if err := ioutil.WriteFile(path, []byte(`{"colors":{"running":"not-a-color"}}`), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	select {
+	case <-changed:
+		t.Fatal("onChange fired for a config that failed to parse")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	if _, ok := cw.Config().Colors["running"]; !ok {
+		t.Fatal("previous config was not kept live after a bad reload")
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// display renders status to the terminal, reading Colors from cw on
+// every tick so edits to the config file take effect immediately
+// instead of requiring a restart.
+type display struct {
+	cw    *ConfigWatcher
+	wheel Wheel
+}
+
+func newDisplay(cw *ConfigWatcher) *display {
+	return &display{cw: cw}
+}
+
+func (d *display) render(status Status) string {
+	label := status.State.String()
+	if color, ok := d.cw.Config().Colors[strings.ToLower(label)]; ok {
+		label = color.Sprint(label)
+	}
+	message := ""
+	if status.Task != nil {
+		message = status.Task.Message
+	}
+	return fmt.Sprintf("\r%s %s %s remaining: %s   ",
+		d.wheel.String(), label, message, status.Remaining.Round(time.Second))
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultSocketPath is where `pomo serve` listens and where the CLI
+// looks for a running daemon, honoring XDG_RUNTIME_DIR when set.
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "pomo.sock")
+	}
+	return filepath.Join(os.TempDir(), "pomo.sock")
+}
+
+// Status is a snapshot of the daemon's timer, returned by Status and
+// streamed by Subscribe.
+type Status struct {
+	State     State         `json:"state"`
+	Task      *Task         `json:"task,omitempty"`
+	Remaining time.Duration `json:"remaining"`
+	// Done is true once COMPLETE means "nothing left to run", as
+	// opposed to a COMPLETE that's merely waiting on a snooze/break
+	// notification action.
+	Done bool `json:"done"`
+}
+
+// TimerController is the state machine a daemon drives; it is
+// implemented by the pomo main loop.
+type TimerController interface {
+	Start(task *Task) error
+	Pause() error
+	Resume() error
+	Status() Status
+	// Subscribe returns a channel of every state transition and a
+	// cancel func to stop receiving them.
+	Subscribe() (<-chan Status, func())
+}
+
+type rpcRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Server is the `pomo serve` daemon: it owns the one TimerController
+// in the process and lets every other `pomo` invocation drive it over
+// a Unix-domain socket instead of running its own timer.
+//
+// Windows support would dial a named pipe the same way via
+// github.com/Microsoft/go-winio; that dependency isn't part of this
+// tree yet, so Serve/Dial below are Unix-only.
+type Server struct {
+	listener net.Listener
+	timer    TimerController
+}
+
+// Serve starts listening on socketPath, removing any stale socket left
+// behind by a daemon that didn't shut down cleanly.
+func Serve(socketPath string, timer TimerController) (*Server, error) {
+	if _, err := os.Stat(socketPath); err == nil {
+		os.Remove(socketPath)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: listener, timer: timer}
+	go s.acceptLoop()
+	return s, nil
+}
+
+// Close stops accepting connections and removes the socket file.
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Server) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		var req rpcRequest
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		if !s.dispatch(enc, req) {
+			return
+		}
+	}
+}
+
+// dispatch runs one request and replies on enc. It returns false when
+// the connection should be closed, which is only true after streaming
+// Subscribe results ends.
+func (s *Server) dispatch(enc *json.Encoder, req rpcRequest) bool {
+	switch req.Method {
+	case "Start":
+		var task Task
+		if err := json.Unmarshal(req.Params, &task); err != nil {
+			enc.Encode(rpcResponse{Error: err.Error()})
+			return true
+		}
+		if err := s.timer.Start(&task); err != nil {
+			enc.Encode(rpcResponse{Error: err.Error()})
+			return true
+		}
+		enc.Encode(rpcResponse{})
+	case "Pause":
+		if err := s.timer.Pause(); err != nil {
+			enc.Encode(rpcResponse{Error: err.Error()})
+			return true
+		}
+		enc.Encode(rpcResponse{})
+	case "Resume":
+		if err := s.timer.Resume(); err != nil {
+			enc.Encode(rpcResponse{Error: err.Error()})
+			return true
+		}
+		enc.Encode(rpcResponse{})
+	case "Status":
+		raw, err := json.Marshal(s.timer.Status())
+		if err != nil {
+			enc.Encode(rpcResponse{Error: err.Error()})
+			return true
+		}
+		enc.Encode(rpcResponse{Result: raw})
+	case "Subscribe":
+		updates, cancel := s.timer.Subscribe()
+		defer cancel()
+		for status := range updates {
+			raw, err := json.Marshal(status)
+			if err != nil {
+				return false
+			}
+			if err := enc.Encode(rpcResponse{Result: raw}); err != nil {
+				return false
+			}
+		}
+		return false
+	default:
+		enc.Encode(rpcResponse{Error: fmt.Sprintf("unknown method: %s", req.Method)})
+	}
+	return true
+}
+
+// Client talks to a running Server over its Unix-domain socket. It
+// backs `pomo start`, `pomo status` and `pomo tail` once the CLI has
+// detected a daemon at DefaultSocketPath.
+type Client struct {
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// Dial connects to a daemon already listening on socketPath.
+func Dial(socketPath string) (*Client, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(conn)}, nil
+}
+
+// IsRunning reports whether a daemon is listening on socketPath.
+func IsRunning(socketPath string) bool {
+	client, err := Dial(socketPath)
+	if err != nil {
+		return false
+	}
+	defer client.Close()
+	_, err = client.Status()
+	return err == nil
+}
+
+// Close disconnects from the daemon.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) call(method string, params, result interface{}) error {
+	var raw json.RawMessage
+	if params != nil {
+		b, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		raw = b
+	}
+	if err := c.enc.Encode(rpcRequest{Method: method, Params: raw}); err != nil {
+		return err
+	}
+	var resp rpcResponse
+	if err := c.dec.Decode(&resp); err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf(resp.Error)
+	}
+	if result != nil && resp.Result != nil {
+		return json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
+
+// Start asks the daemon to begin task.
+func (c *Client) Start(task *Task) error {
+	return c.call("Start", task, nil)
+}
+
+// Pause asks the daemon to pause its current pomodoro.
+func (c *Client) Pause() error {
+	return c.call("Pause", nil, nil)
+}
+
+// Resume asks the daemon to resume a paused pomodoro.
+func (c *Client) Resume() error {
+	return c.call("Resume", nil, nil)
+}
+
+// Status fetches the daemon's current Status.
+func (c *Client) Status() (Status, error) {
+	var status Status
+	err := c.call("Status", nil, &status)
+	return status, err
+}
+
+// Subscribe streams every state transition the daemon makes until the
+// connection is closed. It backs `pomo tail`.
+func (c *Client) Subscribe() (<-chan Status, error) {
+	if err := c.enc.Encode(rpcRequest{Method: "Subscribe"}); err != nil {
+		return nil, err
+	}
+	updates := make(chan Status)
+	go func() {
+		defer close(updates)
+		for {
+			var resp rpcResponse
+			if err := c.dec.Decode(&resp); err != nil {
+				return
+			}
+			if resp.Error != "" {
+				return
+			}
+			var status Status
+			if err := json.Unmarshal(resp.Result, &status); err != nil {
+				return
+			}
+			updates <- status
+		}
+	}()
+	return updates, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeController struct {
+	status  Status
+	updates chan Status
+}
+
+func newFakeController() *fakeController {
+	return &fakeController{
+		status:  Status{State: RUNNING},
+		updates: make(chan Status, 1),
+	}
+}
+
+func (f *fakeController) Start(task *Task) error {
+	f.status.Task = task
+	f.status.State = RUNNING
+	return nil
+}
+
+func (f *fakeController) Pause() error {
+	f.status.State = PAUSED
+	return nil
+}
+
+func (f *fakeController) Resume() error {
+	f.status.State = RUNNING
+	return nil
+}
+
+func (f *fakeController) Status() Status {
+	return f.status
+}
+
+func (f *fakeController) Subscribe() (<-chan Status, func()) {
+	return f.updates, func() {}
+}
+
+func TestServerClientRoundTrip(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "pomo.sock")
+	controller := newFakeController()
+
+	server, err := Serve(socketPath, controller)
+	if err != nil {
+		t.Fatalf("Serve: %s", err)
+	}
+	defer server.Close()
+
+	client, err := Dial(socketPath)
+	if err != nil {
+		t.Fatalf("Dial: %s", err)
+	}
+	defer client.Close()
+
+	task := &Task{Message: "write tests", Duration: 25 * time.Minute}
+	if err := client.Start(task); err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status: %s", err)
+	}
+	if status.State != RUNNING || status.Task == nil || status.Task.Message != "write tests" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	if err := client.Pause(); err != nil {
+		t.Fatalf("Pause: %s", err)
+	}
+	if controller.status.State != PAUSED {
+		t.Fatalf("expected controller to be paused, got %s", controller.status.State)
+	}
+
+	if err := client.Resume(); err != nil {
+		t.Fatalf("Resume: %s", err)
+	}
+	if controller.status.State != RUNNING {
+		t.Fatalf("expected controller to be running, got %s", controller.status.State)
+	}
+}
+
+func TestClientSubscribeStreamsUpdates(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "pomo.sock")
+	controller := newFakeController()
+
+	server, err := Serve(socketPath, controller)
+	if err != nil {
+		t.Fatalf("Serve: %s", err)
+	}
+	defer server.Close()
+
+	client, err := Dial(socketPath)
+	if err != nil {
+		t.Fatalf("Dial: %s", err)
+	}
+	defer client.Close()
+
+	updates, err := client.Subscribe()
+	if err != nil {
+		t.Fatalf("Subscribe: %s", err)
+	}
+
+	controller.updates <- Status{State: BREAKING}
+	select {
+	case status := <-updates:
+		if status.State != BREAKING {
+			t.Fatalf("expected BREAKING, got %s", status.State)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for subscribed update")
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kevinschoon/pomo/store"
+)
+
+// pomoDir is where pomo keeps its config, database and icon, creating
+// it on first run.
+func pomoDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".pomo")
+	return dir, os.MkdirAll(dir, 0755)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: pomo <start|pause|resume|status|tail|serve|history|stats|export> [args]")
+		os.Exit(1)
+	}
+	args := os.Args[2:]
+	var err error
+	switch os.Args[1] {
+	case "start":
+		err = cmdStart(args)
+	case "pause":
+		err = cmdPause(args)
+	case "resume":
+		err = cmdResume(args)
+	case "status":
+		err = cmdStatus(args)
+	case "tail":
+		err = cmdTail(args)
+	case "serve":
+		err = cmdServe(args)
+	case "history":
+		err = cmdHistory(args)
+	case "stats":
+		err = cmdStats(args)
+	case "export":
+		err = cmdExport(args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "pomo:", err)
+		os.Exit(1)
+	}
+}
+
+// cmdStart parses `pomo start [--duration 25m] [--tags a,b] message...`
+// and either hands the task to a running daemon or runs it in this
+// process when no daemon is reachable.
+func cmdStart(args []string) error {
+	fs := flag.NewFlagSet("start", flag.ExitOnError)
+	duration := fs.Duration("duration", 25*time.Minute, "pomodoro duration")
+	n := fs.Int("n", 1, "number of pomodoros")
+	tags := fs.String("tags", "", "comma separated tags")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	task := &Task{
+		Message:    strings.Join(fs.Args(), " "),
+		Duration:   *duration,
+		NPomodoros: *n,
+	}
+	if *tags != "" {
+		task.Tags = strings.Split(*tags, ",")
+	}
+
+	socketPath := DefaultSocketPath()
+	if IsRunning(socketPath) {
+		client, err := Dial(socketPath)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		return client.Start(task)
+	}
+
+	timer, cw, err := newStandaloneTimer()
+	if err != nil {
+		return err
+	}
+	if err := timer.Start(task); err != nil {
+		return err
+	}
+	return watchAndPrint(timer, newDisplay(cw))
+}
+
+// watchAndPrint prints every status update to stdout until the task
+// reaches COMPLETE with no more pomodoros left to run.
+func watchAndPrint(timer *Timer, d *display) error {
+	updates, cancel := timer.Subscribe()
+	defer cancel()
+	for status := range updates {
+		fmt.Print(d.render(status))
+		if status.Done {
+			fmt.Println()
+			return nil
+		}
+	}
+	return nil
+}
+
+func cmdPause(args []string) error {
+	client, err := dialRunningDaemon()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Pause()
+}
+
+func cmdResume(args []string) error {
+	client, err := dialRunningDaemon()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Resume()
+}
+
+// cmdStatus prints the daemon's current status. It backs `pomo
+// status`, cheap enough to poll from a tmux/i3bar/polybar status line.
+func cmdStatus(args []string) error {
+	client, err := dialRunningDaemon()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	status, err := client.Status()
+	if err != nil {
+		return err
+	}
+	if status.Task == nil {
+		fmt.Println(status.State)
+		return nil
+	}
+	fmt.Printf("%s %s %s\n", status.State, status.Task.Message, status.Remaining.Round(time.Second))
+	return nil
+}
+
+// cmdTail streams every state transition the daemon makes, for editor
+// plugins or scripts that want to react to e.g. RUNNING->BREAKING.
+func cmdTail(args []string) error {
+	client, err := dialRunningDaemon()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	updates, err := client.Subscribe()
+	if err != nil {
+		return err
+	}
+	for status := range updates {
+		fmt.Printf("%s remaining=%s\n", status.State, status.Remaining.Round(time.Second))
+	}
+	return nil
+}
+
+// cmdServe runs the daemon: a single Timer, driven over the Unix
+// socket by every other `pomo` invocation.
+func cmdServe(args []string) error {
+	timer, _, err := newStandaloneTimer()
+	if err != nil {
+		return err
+	}
+	server, err := Serve(DefaultSocketPath(), timer)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+	select {} // run until killed
+}
+
+func dialRunningDaemon() (*Client, error) {
+	socketPath := DefaultSocketPath()
+	if !IsRunning(socketPath) {
+		return nil, fmt.Errorf("no pomo daemon running, start one with `pomo serve`")
+	}
+	return Dial(socketPath)
+}
+
+// newStandaloneTimer wires a Timer to the on-disk config (notifiers,
+// colors, live-reloaded) and task store, the shared setup used by both
+// `pomo serve` and a daemon-less `pomo start`. The returned
+// ConfigWatcher lets the caller build a display that picks up Colors
+// edits without restarting.
+func newStandaloneTimer() (*Timer, *ConfigWatcher, error) {
+	dir, err := pomoDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := store.Open(filepath.Join(dir, "pomo.db"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iconPath := filepath.Join(dir, "tomato-icon.png")
+	timer := NewTimer(NoopNotifier{}, db)
+
+	configPath := filepath.Join(dir, "config.json")
+	applyConfig := func(cfg *Config) {
+		notifier, err := NewNotifiers(cfg, iconPath)
+		if err != nil {
+			log.Printf("notifiers: keeping previous notifier stack, building from %s failed: %s", configPath, err)
+			return
+		}
+		timer.SetNotifier(notifier)
+	}
+	watcher, err := WatchConfig(configPath, applyConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	applyConfig(watcher.Config())
+
+	return timer, watcher, nil
+}
+
+func parseSince(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if strings.HasSuffix(s, "d") || strings.HasSuffix(s, "w") {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("bad --since value: %s", s)
+		}
+		days := n
+		if strings.HasSuffix(s, "w") {
+			days *= 7
+		}
+		return time.Now().AddDate(0, 0, -days), nil
+	}
+	return time.Time{}, fmt.Errorf("bad --since value: %s (want e.g. 7d, 2w, 36h)", s)
+}
+
+func openStore() (*store.Store, error) {
+	dir, err := pomoDir()
+	if err != nil {
+		return nil, err
+	}
+	return store.Open(filepath.Join(dir, "pomo.db"))
+}
+
+func cmdHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	tag := fs.String("tag", "", "only show tasks with this tag")
+	since := fs.String("since", "", "only show tasks with activity since, e.g. 7d")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	filter := store.TaskFilter{Tag: *tag}
+	if *since != "" {
+		filter.Since, err = parseSince(*since)
+		if err != nil {
+			return err
+		}
+	}
+	return RunHistory(os.Stdout, db, filter)
+}
+
+func cmdStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	since := fs.String("since", "7d", "report pomodoros completed since, e.g. 7d, 2w, 36h")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sinceTime, err := parseSince(*since)
+	if err != nil {
+		return err
+	}
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return RunStats(os.Stdout, db, sinceTime)
+}
+
+func cmdExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "json", "csv or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return RunExport(os.Stdout, db, *format)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	for _, s := range []string{"36h", "7d", "2w"} {
+		got, err := parseSince(s)
+		if err != nil {
+			t.Fatalf("parseSince(%q): %s", s, err)
+		}
+		if !got.Before(time.Now()) {
+			t.Fatalf("parseSince(%q) = %s, want a time in the past", s, got)
+		}
+	}
+
+	if _, err := parseSince("bogus"); err == nil {
+		t.Fatal("expected an error for an unparseable --since value")
+	}
+}
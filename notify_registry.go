@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// NotifierFactory builds a Notifier from a parsed notifier URL and the
+// shared tomato icon path.
+type NotifierFactory func(u *url.URL, iconPath string) (Notifier, error)
+
+var notifierRegistry = map[string]NotifierFactory{}
+
+// RegisterNotifier adds a notifier factory under scheme, e.g.
+// RegisterNotifier("discord", newDiscordNotifier). Schemes must be
+// unique; registering the same scheme twice panics at init time.
+func RegisterNotifier(scheme string, factory NotifierFactory) {
+	if _, ok := notifierRegistry[scheme]; ok {
+		panic(fmt.Sprintf("notifier scheme already registered: %s", scheme))
+	}
+	notifierRegistry[scheme] = factory
+}
+
+func init() {
+	RegisterNotifier("libnotify", func(u *url.URL, iconPath string) (Notifier, error) {
+		return NewLibNotifier(iconPath), nil
+	})
+	RegisterNotifier("desktop", func(u *url.URL, iconPath string) (Notifier, error) {
+		return newXnotifier(iconPath), nil
+	})
+	RegisterNotifier("webhook", newWebhookNotifier)
+	RegisterNotifier("exec", newExecNotifier)
+	RegisterNotifier("discord", newDiscordNotifier)
+}
+
+// defaultNotifierURLs returns the notifier stack used when the user's
+// Config does not list any Notifiers, mirroring the old hardcoded
+// per-OS constructors.
+func defaultNotifierURLs() []string {
+	switch runtime.GOOS {
+	case "linux":
+		return []string{"libnotify://"}
+	default:
+		return []string{"desktop://"}
+	}
+}
+
+// NewNotifiers builds the configured notifier stack, falling back to
+// defaultNotifierURLs when cfg has none. A single entry is returned
+// unwrapped; more than one is fanned out via MultiNotifier.
+func NewNotifiers(cfg *Config, iconPath string) (Notifier, error) {
+	rawURLs := cfg.Notifiers
+	if len(rawURLs) == 0 {
+		rawURLs = defaultNotifierURLs()
+	}
+	notifiers := make([]Notifier, 0, len(rawURLs))
+	for _, raw := range rawURLs {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("bad notifier url %q: %s", raw, err)
+		}
+		factory, ok := notifierRegistry[u.Scheme]
+		if !ok {
+			return nil, fmt.Errorf("unknown notifier scheme: %s", u.Scheme)
+		}
+		notifier, err := factory(u, iconPath)
+		if err != nil {
+			return nil, fmt.Errorf("building notifier %q: %s", raw, err)
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	if len(notifiers) == 1 {
+		return notifiers[0], nil
+	}
+	return MultiNotifier(notifiers), nil
+}
+
+// MultiNotifier fans a single Notify/NotifyWithActions call out to
+// every backend it wraps and aggregates their errors.
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) Notify(title, body string) error {
+	var errs []string
+	for _, n := range m {
+		if err := n.Notify(title, body); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("notify: %v", errs)
+	}
+	return nil
+}
+
+// NotifyWithActions forwards actions to every backend and merges their
+// result channels into one, closing it once every backend's channel
+// has closed, matching the contract on Notifier.NotifyWithActions.
+func (m MultiNotifier) NotifyWithActions(n Notification, actions []Action) (<-chan ActionResult, error) {
+	results := make(chan ActionResult, len(m))
+	var wg sync.WaitGroup
+	var errs []string
+	for _, notifier := range m {
+		ch, err := notifier.NotifyWithActions(n, actions)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		wg.Add(1)
+		go func(ch <-chan ActionResult) {
+			defer wg.Done()
+			for result := range ch {
+				results <- result
+			}
+		}(ch)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	if len(errs) > 0 {
+		return results, fmt.Errorf("notify: %v", errs)
+	}
+	return results, nil
+}
+
+// webhookNotifier POSTs a JSON body to u for every notification.
+type webhookNotifier struct {
+	url string
+}
+
+func newWebhookNotifier(u *url.URL, iconPath string) (Notifier, error) {
+	dest := *u
+	dest.Scheme = "https"
+	if u.Query().Get("insecure") == "true" {
+		dest.Scheme = "http"
+	}
+	return webhookNotifier{url: dest.String()}, nil
+}
+
+// webhookPayload is the JSON body POSTed for every notification. Task
+// is only populated when the caller attached one via Notification.Task
+// (NotifyWithActions); plain Notify calls have no task to report.
+type webhookPayload struct {
+	Title     string  `json:"title"`
+	Body      string  `json:"body"`
+	Category  string  `json:"category,omitempty"`
+	Urgency   Urgency `json:"urgency"`
+	Transient bool    `json:"transient,omitempty"`
+	Resident  bool    `json:"resident,omitempty"`
+	Task      *Task   `json:"task,omitempty"`
+}
+
+func (w webhookNotifier) post(n Notification) error {
+	payload, err := json.Marshal(webhookPayload{
+		Title:     n.Title,
+		Body:      n.Body,
+		Category:  n.Hints.Category,
+		Urgency:   n.Hints.Urgency,
+		Transient: n.Hints.Transient,
+		Resident:  n.Hints.Resident,
+		Task:      n.Task,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(w.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", w.url, resp.Status)
+	}
+	return nil
+}
+
+func (w webhookNotifier) Notify(title, body string) error {
+	return w.post(Notification{Title: title, Body: body})
+}
+
+func (w webhookNotifier) NotifyWithActions(n Notification, _ []Action) (<-chan ActionResult, error) {
+	ch := make(chan ActionResult)
+	close(ch)
+	return ch, w.post(n)
+}
+
+// discordNotifier posts to a Discord webhook, e.g.
+// "discord://token@id" for the webhook at
+// https://discord.com/api/webhooks/id/token. It is the first of the
+// shoutrrr-style chat/mobile/home-automation backends Config.Notifiers
+// documents.
+type discordNotifier struct {
+	url string
+}
+
+func newDiscordNotifier(u *url.URL, iconPath string) (Notifier, error) {
+	if u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, fmt.Errorf("discord notifier requires discord://token@id")
+	}
+	return discordNotifier{
+		url: fmt.Sprintf("https://discord.com/api/webhooks/%s/%s", u.Host, u.User.Username()),
+	}, nil
+}
+
+type discordPayload struct {
+	Content string `json:"content"`
+}
+
+func (d discordNotifier) post(n Notification) error {
+	content := n.Title
+	if n.Body != "" {
+		content = fmt.Sprintf("%s: %s", n.Title, n.Body)
+	}
+	payload, err := json.Marshal(discordPayload{Content: content})
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(d.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("discord webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (d discordNotifier) Notify(title, body string) error {
+	return d.post(Notification{Title: title, Body: body})
+}
+
+func (d discordNotifier) NotifyWithActions(n Notification, _ []Action) (<-chan ActionResult, error) {
+	ch := make(chan ActionResult)
+	close(ch)
+	return ch, d.post(n)
+}
+
+// execNotifier runs a user-provided command for every notification,
+// passing the task and state as environment variables. Query string
+// parameters on the notifier URL (e.g. exec:///usr/bin/say?voice=Alex)
+// become "--key value" flags, sorted by key for determinism.
+type execNotifier struct {
+	path string
+	args []string
+}
+
+func newExecNotifier(u *url.URL, iconPath string) (Notifier, error) {
+	if u.Path == "" {
+		return nil, fmt.Errorf("exec notifier requires a path, e.g. exec:///usr/bin/say")
+	}
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		args = append(args, "--"+key, query.Get(key))
+	}
+	return execNotifier{path: u.Path, args: args}, nil
+}
+
+func (e execNotifier) run(title, body, state string) error {
+	cmd := exec.Command(e.path, e.args...)
+	cmd.Env = append(os.Environ(),
+		"POMO_TASK="+title,
+		"POMO_REMAINING="+body,
+		"POMO_STATE="+state,
+	)
+	return cmd.Run()
+}
+
+func (e execNotifier) Notify(title, body string) error {
+	return e.run(title, body, "")
+}
+
+func (e execNotifier) NotifyWithActions(n Notification, _ []Action) (<-chan ActionResult, error) {
+	ch := make(chan ActionResult)
+	close(ch)
+	return ch, e.run(n.Title, n.Body, n.Hints.Category)
+}
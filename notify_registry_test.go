@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+type fakeNotifier struct {
+	results []ActionResult
+	err     error
+}
+
+func (f fakeNotifier) Notify(string, string) error { return f.err }
+
+func (f fakeNotifier) NotifyWithActions(Notification, []Action) (<-chan ActionResult, error) {
+	ch := make(chan ActionResult, len(f.results))
+	for _, r := range f.results {
+		ch <- r
+	}
+	close(ch)
+	return ch, f.err
+}
+
+func TestMultiNotifierNotifyAggregatesErrors(t *testing.T) {
+	m := MultiNotifier{
+		fakeNotifier{},
+		fakeNotifier{err: errTest},
+	}
+	if err := m.Notify("title", "body"); err == nil {
+		t.Fatal("expected an aggregated error, got nil")
+	}
+}
+
+func TestMultiNotifierNotifyWithActionsClosesResults(t *testing.T) {
+	m := MultiNotifier{
+		fakeNotifier{results: []ActionResult{{ActionID: "a"}}},
+		fakeNotifier{results: []ActionResult{{ActionID: "b"}}},
+	}
+	results, err := m.NotifyWithActions(Notification{}, nil)
+	if err != nil {
+		t.Fatalf("NotifyWithActions: %s", err)
+	}
+
+	seen := map[string]bool{}
+	for r := range results {
+		seen["a"] = seen["a"] || r.ActionID == "a"
+		seen["b"] = seen["b"] || r.ActionID == "b"
+	}
+	if !seen["a"] || !seen["b"] {
+		t.Fatalf("expected results from both backends, got %v", seen)
+	}
+}
+
+func TestNewDiscordNotifierRequiresTokenAndID(t *testing.T) {
+	if _, err := newDiscordNotifier(mustParseURL(t, "discord://"), ""); err == nil {
+		t.Fatal("expected an error for a discord url with no token/id")
+	}
+	n, err := newDiscordNotifier(mustParseURL(t, "discord://token@id"), "")
+	if err != nil {
+		t.Fatalf("newDiscordNotifier: %s", err)
+	}
+	d := n.(discordNotifier)
+	want := "https://discord.com/api/webhooks/id/token"
+	if d.url != want {
+		t.Fatalf("url = %q, want %q", d.url, want)
+	}
+}
+
+func TestNewNotifiersResolvesDocumentedDiscordExample(t *testing.T) {
+	// This is the exact example Config.Notifiers' doc comment cites;
+	// it must resolve rather than fail with "unknown notifier scheme".
+	cfg := &Config{Notifiers: []string{"discord://token@id"}}
+	if _, err := NewNotifiers(cfg, ""); err != nil {
+		t.Fatalf("NewNotifiers: %s", err)
+	}
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %s", raw, err)
+	}
+	return u
+}
+
+type testError string
+
+func (e testError) Error() string { return string(e) }
+
+var errTest = testError("boom")
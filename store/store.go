@@ -0,0 +1,327 @@
+// Package store provides durable, queryable storage for tasks and
+// completed pomodoros, backed by a pure-Go SQLite driver so builds
+// stay CGO-free.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// schema is applied on every Open so new installs and upgrades both
+// converge on the same tables; CREATE TABLE/INDEX IF NOT EXISTS keeps
+// re-running it across versions a no-op.
+const schema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	id          INTEGER PRIMARY KEY,
+	message     TEXT NOT NULL,
+	tags        TEXT NOT NULL DEFAULT '',
+	n_pomodoros INTEGER NOT NULL DEFAULT 0,
+	duration_ns INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS pomodoros (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id  INTEGER NOT NULL REFERENCES tasks(id),
+	start_ns INTEGER NOT NULL,
+	end_ns   INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pomodoros_task_id ON pomodoros(task_id);
+CREATE INDEX IF NOT EXISTS idx_pomodoros_end_ns ON pomodoros(end_ns);
+`
+
+// Pomodoro is a single completed work interval against a Task.
+type Pomodoro struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns how long the pomodoro ran.
+func (p Pomodoro) Duration() time.Duration {
+	return p.End.Sub(p.Start)
+}
+
+// Task is the persisted form of a pomo task.
+type Task struct {
+	ID         int
+	Message    string
+	Tags       []string
+	NPomodoros int
+	Duration   time.Duration
+	Pomodoros  []Pomodoro
+}
+
+// TaskFilter narrows ListTasks. A zero value matches every task.
+type TaskFilter struct {
+	Tag   string
+	Since time.Time
+}
+
+// TagTotal is the aggregate time spent against a single tag.
+type TagTotal struct {
+	Tag      string
+	Count    int
+	Duration time.Duration
+}
+
+// DayTotal is the aggregate pomodoro time completed on a single day.
+type DayTotal struct {
+	Day      string // YYYY-MM-DD, local time
+	Duration time.Duration
+}
+
+// Store is a handle to the on-disk SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens path, creating it and running the schema migration if it
+// doesn't already exist.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating schema: %s", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close releases the underlying database handle.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveTask inserts or updates t. A zero t.ID is treated as "new task"
+// and left for SQLite to autoincrement rather than written literally,
+// otherwise every new task would collide on id 0 and overwrite
+// whichever one was saved first.
+func (s *Store) SaveTask(t Task) (int, error) {
+	tags := strings.Join(t.Tags, ",")
+
+	if t.ID == 0 {
+		res, err := s.db.Exec(
+			`INSERT INTO tasks (message, tags, n_pomodoros, duration_ns) VALUES (?, ?, ?, ?)`,
+			t.Message, tags, t.NPomodoros, int64(t.Duration),
+		)
+		if err != nil {
+			return 0, err
+		}
+		id, err := res.LastInsertId()
+		return int(id), err
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO tasks (id, message, tags, n_pomodoros, duration_ns)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+			message = excluded.message,
+			tags = excluded.tags,
+			n_pomodoros = excluded.n_pomodoros,
+			duration_ns = excluded.duration_ns`,
+		t.ID, t.Message, tags, t.NPomodoros, int64(t.Duration),
+	)
+	return t.ID, err
+}
+
+// SavePomodoro records a completed pomodoro against taskID.
+func (s *Store) SavePomodoro(taskID int, p Pomodoro) error {
+	_, err := s.db.Exec(
+		`INSERT INTO pomodoros (task_id, start_ns, end_ns) VALUES (?, ?, ?)`,
+		taskID, p.Start.UnixNano(), p.End.UnixNano(),
+	)
+	return err
+}
+
+// ListTasks returns tasks matching filter, newest first. A zero
+// TaskFilter returns every task.
+func (s *Store) ListTasks(filter TaskFilter) ([]Task, error) {
+	query := `SELECT id, message, tags, n_pomodoros, duration_ns FROM tasks WHERE 1 = 1`
+	args := []interface{}{}
+	if filter.Tag != "" {
+		query += ` AND (',' || tags || ',') LIKE ?`
+		args = append(args, "%,"+filter.Tag+",%")
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND id IN (SELECT task_id FROM pomodoros WHERE end_ns >= ?)`
+		args = append(args, filter.Since.UnixNano())
+	}
+	query += ` ORDER BY id DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		var (
+			t        Task
+			tags     string
+			duration int64
+		)
+		if err := rows.Scan(&t.ID, &t.Message, &tags, &t.NPomodoros, &duration); err != nil {
+			return nil, err
+		}
+		if tags != "" {
+			t.Tags = strings.Split(tags, ",")
+		}
+		t.Duration = time.Duration(duration)
+		pomodoros, err := s.pomodorosForTask(t.ID)
+		if err != nil {
+			return nil, err
+		}
+		t.Pomodoros = pomodoros
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+func (s *Store) pomodorosForTask(taskID int) ([]Pomodoro, error) {
+	rows, err := s.db.Query(
+		`SELECT start_ns, end_ns FROM pomodoros WHERE task_id = ? ORDER BY start_ns`, taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pomodoros []Pomodoro
+	for rows.Next() {
+		var start, end int64
+		if err := rows.Scan(&start, &end); err != nil {
+			return nil, err
+		}
+		pomodoros = append(pomodoros, Pomodoro{
+			Start: time.Unix(0, start),
+			End:   time.Unix(0, end),
+		})
+	}
+	return pomodoros, rows.Err()
+}
+
+// CompletedSince returns every pomodoro that ended at or after t.
+func (s *Store) CompletedSince(t time.Time) ([]Pomodoro, error) {
+	rows, err := s.db.Query(
+		`SELECT start_ns, end_ns FROM pomodoros WHERE end_ns >= ? ORDER BY end_ns`, t.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pomodoros []Pomodoro
+	for rows.Next() {
+		var start, end int64
+		if err := rows.Scan(&start, &end); err != nil {
+			return nil, err
+		}
+		pomodoros = append(pomodoros, Pomodoro{Start: time.Unix(0, start), End: time.Unix(0, end)})
+	}
+	return pomodoros, rows.Err()
+}
+
+// TagSummary aggregates completed pomodoro time per tag for pomodoros
+// that ended at or after since. A zero since includes every pomodoro.
+func (s *Store) TagSummary(since time.Time) ([]TagTotal, error) {
+	rows, err := s.db.Query(
+		`SELECT t.tags, COUNT(p.id), COALESCE(SUM(p.end_ns - p.start_ns), 0)
+		 FROM tasks t JOIN pomodoros p ON p.task_id = t.id
+		 WHERE p.end_ns >= ?
+		 GROUP BY t.tags`,
+		since.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := map[string]TagTotal{}
+	for rows.Next() {
+		var tags string
+		var count int
+		var durationNS int64
+		if err := rows.Scan(&tags, &count, &durationNS); err != nil {
+			return nil, err
+		}
+		for _, tag := range strings.Split(tags, ",") {
+			if tag == "" {
+				continue
+			}
+			total := totals[tag]
+			total.Tag = tag
+			total.Count += count
+			total.Duration += time.Duration(durationNS)
+			totals[tag] = total
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	summary := make([]TagTotal, 0, len(totals))
+	for _, total := range totals {
+		summary = append(summary, total)
+	}
+	return summary, nil
+}
+
+// DailyDurations aggregates completed pomodoro time per calendar day
+// since t.
+func (s *Store) DailyDurations(since time.Time) ([]DayTotal, error) {
+	rows, err := s.db.Query(
+		`SELECT date(end_ns / 1000000000, 'unixepoch', 'localtime') AS day,
+			SUM(end_ns - start_ns)
+		 FROM pomodoros
+		 WHERE end_ns >= ?
+		 GROUP BY day
+		 ORDER BY day`,
+		since.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []DayTotal
+	for rows.Next() {
+		var d DayTotal
+		var durationNS int64
+		if err := rows.Scan(&d.Day, &durationNS); err != nil {
+			return nil, err
+		}
+		d.Duration = time.Duration(durationNS)
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
+
+// StreakDays returns the number of consecutive days, ending today,
+// with at least one completed pomodoro.
+func (s *Store) StreakDays() (int, error) {
+	days, err := s.DailyDurations(time.Time{})
+	if err != nil {
+		return 0, err
+	}
+	haveDay := map[string]bool{}
+	for _, d := range days {
+		haveDay[d.Day] = true
+	}
+
+	streak := 0
+	for day := time.Now(); ; day = day.AddDate(0, 0, -1) {
+		if !haveDay[day.Format("2006-01-02")] {
+			break
+		}
+		streak++
+	}
+	return streak, nil
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "pomo.db"))
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSaveTaskAssignsDistinctIDs(t *testing.T) {
+	s := openTestStore(t)
+
+	firstID, err := s.SaveTask(Task{Message: "first", NPomodoros: 1})
+	if err != nil {
+		t.Fatalf("SaveTask(first): %s", err)
+	}
+	secondID, err := s.SaveTask(Task{Message: "second", NPomodoros: 1})
+	if err != nil {
+		t.Fatalf("SaveTask(second): %s", err)
+	}
+	if firstID == secondID {
+		t.Fatalf("expected distinct ids, got %d and %d", firstID, secondID)
+	}
+
+	tasks, err := s.ListTasks(TaskFilter{})
+	if err != nil {
+		t.Fatalf("ListTasks: %s", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+}
+
+func TestSaveTaskUpdatesExistingID(t *testing.T) {
+	s := openTestStore(t)
+
+	id, err := s.SaveTask(Task{Message: "draft", NPomodoros: 1})
+	if err != nil {
+		t.Fatalf("SaveTask: %s", err)
+	}
+	if _, err := s.SaveTask(Task{ID: id, Message: "final", NPomodoros: 2}); err != nil {
+		t.Fatalf("SaveTask(update): %s", err)
+	}
+
+	tasks, err := s.ListTasks(TaskFilter{})
+	if err != nil {
+		t.Fatalf("ListTasks: %s", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(tasks))
+	}
+	if tasks[0].Message != "final" || tasks[0].NPomodoros != 2 {
+		t.Fatalf("update did not apply, got %+v", tasks[0])
+	}
+}
+
+func TestTagSummaryAndStreak(t *testing.T) {
+	s := openTestStore(t)
+
+	id, err := s.SaveTask(Task{Message: "focus", Tags: []string{"work"}, NPomodoros: 1})
+	if err != nil {
+		t.Fatalf("SaveTask: %s", err)
+	}
+
+	now := time.Now()
+	if err := s.SavePomodoro(id, Pomodoro{Start: now.Add(-25 * time.Minute), End: now}); err != nil {
+		t.Fatalf("SavePomodoro: %s", err)
+	}
+
+	summary, err := s.TagSummary(time.Time{})
+	if err != nil {
+		t.Fatalf("TagSummary: %s", err)
+	}
+	if len(summary) != 1 || summary[0].Tag != "work" || summary[0].Count != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	streak, err := s.StreakDays()
+	if err != nil {
+		t.Fatalf("StreakDays: %s", err)
+	}
+	if streak != 1 {
+		t.Fatalf("expected a 1 day streak, got %d", streak)
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/kevinschoon/pomo/store"
+)
+
+// defaultBreakDuration is how long a break lasts when the user accepts
+// the "Start break" action on a COMPLETE notification.
+const defaultBreakDuration = 5 * time.Minute
+
+const (
+	actionStartBreak = "start_break"
+	actionSnooze5m   = "snooze_5m"
+	actionSkip       = "skip"
+)
+
+// Timer is the pomo state machine: it runs one task through its
+// pomodoros, and on every COMPLETE offers the user "Start break",
+// "Snooze 5m" and "Skip" actions via its Notifier, acting on whichever
+// one comes back. It implements TimerController so it can be driven
+// directly or from behind the daemon in ipc.go.
+type Timer struct {
+	mu            sync.Mutex
+	state         State
+	task          *Task
+	breakDuration time.Duration
+	end           time.Time
+	remaining     time.Duration
+	onBreak       bool
+	pomodorosDone int
+	finished      bool
+	subs          []chan Status
+
+	notifier Notifier
+	store    *store.Store
+}
+
+// NewTimer builds a Timer that notifies through notifier and, if st is
+// non-nil, persists completed pomodoros to it.
+func NewTimer(notifier Notifier, st *store.Store) *Timer {
+	if notifier == nil {
+		notifier = NoopNotifier{}
+	}
+	return &Timer{
+		notifier:      notifier,
+		store:         st,
+		breakDuration: defaultBreakDuration,
+	}
+}
+
+// SetNotifier swaps the active notifier, e.g. when a ConfigWatcher
+// reports a new notifier stack.
+func (t *Timer) SetNotifier(notifier Notifier) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.notifier = notifier
+}
+
+// Start begins task's first pomodoro.
+func (t *Timer) Start(task *Task) error {
+	t.mu.Lock()
+	if t.state == RUNNING || t.state == BREAKING {
+		t.mu.Unlock()
+		return fmt.Errorf("timer already running")
+	}
+	t.task = task
+	t.pomodorosDone = 0
+	t.onBreak = false
+	t.finished = false
+	t.mu.Unlock()
+	t.beginPhase(RUNNING, task.Duration)
+	return nil
+}
+
+// Pause freezes the countdown in place.
+func (t *Timer) Pause() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state != RUNNING && t.state != BREAKING {
+		return fmt.Errorf("timer is not running")
+	}
+	t.remaining = time.Until(t.end)
+	t.state = PAUSED
+	return nil
+}
+
+// Resume continues a paused countdown from where it left off.
+func (t *Timer) Resume() error {
+	t.mu.Lock()
+	if t.state != PAUSED {
+		t.mu.Unlock()
+		return fmt.Errorf("timer is not paused")
+	}
+	t.end = time.Now().Add(t.remaining)
+	if t.onBreak {
+		t.state = BREAKING
+	} else {
+		t.state = RUNNING
+	}
+	t.mu.Unlock()
+	go t.run()
+	return nil
+}
+
+// Status returns a snapshot of the timer's current phase.
+func (t *Timer) Status() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := Status{State: t.state, Task: t.task, Done: t.finished}
+	if t.state == RUNNING || t.state == BREAKING {
+		status.Remaining = time.Until(t.end)
+	} else {
+		status.Remaining = t.remaining
+	}
+	return status
+}
+
+// Subscribe returns a channel of status updates (each state transition
+// and each tick of a running countdown) and a cancel func to stop
+// receiving them. Sends never block: a subscriber that has not yet
+// received its previous update misses the new one.
+func (t *Timer) Subscribe() (<-chan Status, func()) {
+	ch := make(chan Status, 1)
+	t.mu.Lock()
+	t.subs = append(t.subs, ch)
+	t.mu.Unlock()
+	cancel := func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		for i, sub := range t.subs {
+			if sub == ch {
+				t.subs = append(t.subs[:i], t.subs[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}
+	return ch, cancel
+}
+
+func (t *Timer) beginPhase(state State, duration time.Duration) {
+	t.mu.Lock()
+	t.state = state
+	t.onBreak = state == BREAKING
+	t.end = time.Now().Add(duration)
+	t.mu.Unlock()
+	t.broadcast()
+	go t.run()
+}
+
+func (t *Timer) run() {
+	ticker := time.NewTicker(RefreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		t.mu.Lock()
+		if t.state != RUNNING && t.state != BREAKING {
+			t.mu.Unlock()
+			return
+		}
+		remaining := time.Until(t.end)
+		if remaining <= 0 {
+			t.state = COMPLETE
+			onBreak := t.onBreak
+			t.mu.Unlock()
+			t.onComplete(onBreak)
+			return
+		}
+		t.mu.Unlock()
+		t.broadcast()
+	}
+}
+
+// onComplete fires when a phase's countdown reaches zero. A completed
+// work pomodoro is persisted and offers the user interactive actions;
+// a completed break simply starts the next work pomodoro.
+func (t *Timer) onComplete(wasBreak bool) {
+	t.broadcast()
+
+	if wasBreak {
+		t.startNextPomodoro()
+		return
+	}
+
+	t.mu.Lock()
+	task := t.task
+	t.pomodorosDone++
+	t.mu.Unlock()
+
+	if t.store != nil && task != nil {
+		pomodoro := store.Pomodoro{Start: t.end.Add(-task.Duration), End: t.end}
+		task.Pomodoros = append(task.Pomodoros, &Pomodoro{Start: pomodoro.Start, End: pomodoro.End})
+		if id, err := t.store.SaveTask(store.Task{
+			ID: task.ID, Message: task.Message, Tags: task.Tags,
+			NPomodoros: task.NPomodoros, Duration: task.Duration,
+		}); err != nil {
+			log.Printf("timer: saving task: %s", err)
+		} else {
+			task.ID = id
+			if err := t.store.SavePomodoro(id, pomodoro); err != nil {
+				log.Printf("timer: saving pomodoro: %s", err)
+			}
+		}
+	}
+
+	t.notifyAndAwaitAction(task)
+}
+
+// notifyAndAwaitAction sends the "Pomodoro complete" action prompt for
+// task and, once the user responds (or the notifier gives up), drives
+// awaitAction off the result. It is the re-askable half of onComplete:
+// snoozing re-runs only this, not the persistence/increment side effects
+// that precede it.
+func (t *Timer) notifyAndAwaitAction(task *Task) {
+	actions := []Action{
+		{ID: actionStartBreak, Label: "Start break"},
+		{ID: actionSnooze5m, Label: "Snooze 5m"},
+		{ID: actionSkip, Label: "Skip"},
+	}
+	results, err := t.notifier.NotifyWithActions(Notification{
+		Title: "Pomodoro complete",
+		Body:  task.Message,
+		Hints: Hints{Urgency: UrgencyNormal, Category: "complete", Resident: true},
+		// Stay on screen until the user acts rather than timing out
+		// mid-break like an ordinary transient notification would.
+		Timeout: -1,
+		Task:    task,
+	}, actions)
+	if err != nil {
+		log.Printf("timer: notify: %s", err)
+	}
+	if results == nil {
+		// A backend that failed outright hands back no channel; treat
+		// it like one that closed without a result.
+		closed := make(chan ActionResult)
+		close(closed)
+		results = closed
+	}
+
+	go t.awaitAction(task, results)
+}
+
+// awaitAction drives the state machine off whichever action the user
+// picked for task's completion. If every notifier backend declined to
+// offer actions (e.g. NoopNotifier) the channel closes without a
+// result, in which case the timer defaults to starting the break
+// rather than stalling forever.
+func (t *Timer) awaitAction(task *Task, results <-chan ActionResult) {
+	result, ok := <-results
+	actionID := actionStartBreak
+	if ok {
+		actionID = result.ActionID
+	}
+	switch actionID {
+	case actionSnooze5m:
+		t.beginPhase(COMPLETE, 0) // hold state while snoozed
+		time.AfterFunc(defaultBreakDuration, func() { t.notifyAndAwaitAction(task) })
+	case actionSkip:
+		t.startNextPomodoro()
+	default: // actionStartBreak, or no action at all
+		t.beginPhase(BREAKING, t.breakDuration)
+	}
+}
+
+func (t *Timer) startNextPomodoro() {
+	t.mu.Lock()
+	task := t.task
+	done := t.pomodorosDone
+	t.mu.Unlock()
+	if task == nil || done >= task.NPomodoros {
+		t.mu.Lock()
+		t.state = COMPLETE
+		t.finished = true
+		t.mu.Unlock()
+		t.broadcast()
+		return
+	}
+	t.beginPhase(RUNNING, task.Duration)
+}
+
+func (t *Timer) broadcast() {
+	status := t.Status()
+	t.mu.Lock()
+	subs := make([]chan Status, len(t.subs))
+	copy(subs, t.subs)
+	t.mu.Unlock()
+	for _, ch := range subs {
+		select {
+		case ch <- status:
+		default:
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kevinschoon/pomo/store"
+)
+
+// actionNotifier hands back a single queued ActionResult per
+// NotifyWithActions call, or none if the queue is empty, letting tests
+// script the user's response to successive "Pomodoro complete" prompts.
+type actionNotifier struct {
+	queue chan string
+	calls chan struct{}
+}
+
+func newActionNotifier() *actionNotifier {
+	return &actionNotifier{
+		queue: make(chan string, 8),
+		calls: make(chan struct{}, 8),
+	}
+}
+
+func (a *actionNotifier) Notify(string, string) error { return nil }
+
+func (a *actionNotifier) NotifyWithActions(Notification, []Action) (<-chan ActionResult, error) {
+	a.calls <- struct{}{}
+	ch := make(chan ActionResult, 1)
+	select {
+	case actionID := <-a.queue:
+		ch <- ActionResult{ActionID: actionID}
+	default:
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestNoopNotifierNotifyWithActionsClosesWithoutResult(t *testing.T) {
+	ch, err := (NoopNotifier{}).NotifyWithActions(Notification{}, nil)
+	if err != nil {
+		t.Fatalf("NotifyWithActions: %s", err)
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("expected the channel to close without a result")
+	}
+}
+
+func TestXnotifierNotifyWithActionsFallsBackToNotify(t *testing.T) {
+	// Notify shells out to an OS-specific backend (notify-send on
+	// Linux) that may not be installed in a test environment, so only
+	// the action-result contract is asserted here, not a nil error.
+	n := newXnotifier(t.TempDir() + "/icon.png")
+	ch, _ := n.NotifyWithActions(Notification{Title: "t", Body: "b"}, nil)
+	if _, ok := <-ch; ok {
+		t.Fatal("expected the channel to close without a result")
+	}
+}
+
+func TestTimerSkipStartsNextPomodoroImmediately(t *testing.T) {
+	notifier := newActionNotifier()
+	notifier.queue <- actionSkip
+	timer := NewTimer(notifier, nil)
+
+	task := &Task{Message: "write code", Duration: time.Millisecond, NPomodoros: 2}
+	if err := timer.Start(task); err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+
+	<-notifier.calls
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for the second pomodoro to start")
+		default:
+		}
+		if timer.Status().State == RUNNING {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTimerSnoozeDoesNotDoubleCountOrPersistTwice(t *testing.T) {
+	db, err := store.Open(t.TempDir() + "/pomo.db")
+	if err != nil {
+		t.Fatalf("store.Open: %s", err)
+	}
+	defer db.Close()
+
+	notifier := newActionNotifier()
+	notifier.queue <- actionSnooze5m
+	notifier.queue <- actionStartBreak
+	timer := NewTimer(notifier, db)
+	timer.breakDuration = time.Millisecond
+
+	// Snooze fires a second "Pomodoro complete" prompt after
+	// defaultBreakDuration (5m), too slow for a test, so drive the
+	// re-ask directly rather than waiting on the real timer.
+	task := &Task{Message: "write code", Duration: time.Millisecond, NPomodoros: 1}
+	if err := timer.Start(task); err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+
+	<-notifier.calls // initial completion prompt, answered with snooze
+	time.Sleep(10 * time.Millisecond)
+	if got := timer.pomodorosDone; got != 1 {
+		t.Fatalf("pomodorosDone = %d, want 1 after the real completion", got)
+	}
+
+	timer.mu.Lock()
+	snoozedTask := timer.task
+	timer.mu.Unlock()
+	timer.notifyAndAwaitAction(snoozedTask) // simulate the snooze timer firing
+
+	<-notifier.calls // re-asked prompt, answered with start_break
+	deadline := time.After(2 * time.Second)
+	for timer.Status().State != BREAKING {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for BREAKING, got %s", timer.Status().State)
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	if got := timer.pomodorosDone; got != 1 {
+		t.Fatalf("pomodorosDone = %d, want 1 (snooze must not re-increment)", got)
+	}
+
+	tasks, err := db.ListTasks(store.TaskFilter{})
+	if err != nil {
+		t.Fatalf("ListTasks: %s", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("expected exactly 1 saved task, got %d", len(tasks))
+	}
+	if got := len(tasks[0].Pomodoros); got != 1 {
+		t.Fatalf("expected exactly 1 saved pomodoro, got %d (snooze must not re-persist)", got)
+	}
+}
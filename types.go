@@ -9,6 +9,7 @@ import (
 
 	"github.com/0xAX/notificator"
 	"github.com/fatih/color"
+	"github.com/godbus/dbus/v5"
 
 	"github.com/kevinschoon/pomo/libnotify"
 )
@@ -64,6 +65,12 @@ func (w *Wheel) String() string {
 // Config represents user preferences
 type Config struct {
 	Colors map[string]*color.Color
+	// Notifiers is a list of notifier URLs, e.g.
+	// "libnotify://", "discord://token@id", "exec:///usr/bin/say".
+	// Each is resolved against the notifier registry in
+	// notify_registry.go. An empty list falls back to
+	// defaultNotifierURLs() for the current OS.
+	Notifiers []string
 }
 
 var colorMap = map[string]*color.Color{
@@ -75,7 +82,8 @@ var colorMap = map[string]*color.Color{
 
 func (c *Config) UnmarshalJSON(raw []byte) error {
 	config := &struct {
-		Colors map[string]string `json:"colors"`
+		Colors    map[string]string `json:"colors"`
+		Notifiers []string          `json:"notifiers"`
 	}{}
 	err := json.Unmarshal(raw, config)
 	if err != nil {
@@ -88,6 +96,7 @@ func (c *Config) UnmarshalJSON(raw []byte) error {
 			return fmt.Errorf("bad color choice: %s", name)
 		}
 	}
+	c.Notifiers = config.Notifiers
 	return nil
 }
 
@@ -142,10 +151,62 @@ func (p Pomodoro) Duration() time.Duration {
 	return (p.End.Sub(p.Start))
 }
 
+// Urgency maps to the libnotify/DBus urgency hint.
+type Urgency byte
+
+const (
+	UrgencyLow Urgency = iota
+	UrgencyNormal
+	UrgencyCritical
+)
+
+// Hints carry the optional DBus notification hints. Transient
+// notifications are removed from history once dismissed, Resident
+// ones stay on screen until the user acts on them.
+type Hints struct {
+	Urgency   Urgency
+	Category  string
+	Transient bool
+	Resident  bool
+}
+
+// Action is a single button offered alongside a notification. ID is
+// returned verbatim on the ActionResult channel when the user picks it.
+type Action struct {
+	ID    string
+	Label string
+}
+
+// ActionResult reports which Action the user invoked.
+type ActionResult struct {
+	ActionID string
+}
+
+// Notification describes a single desktop notification, including the
+// optional hints and timeout a Notifier may use to keep it on screen
+// for the duration of a pomodoro.
+type Notification struct {
+	Title string
+	Body  string
+	Hints Hints
+	// Timeout is how long the notification stays visible. Zero means
+	// the backend default, a negative value means never expire.
+	Timeout time.Duration
+	// Task is the task this notification concerns, if any. Remote
+	// notifiers (webhook, exec) forward it so subscribers get the
+	// same task/pomodoro metadata a desktop popup implies visually.
+	Task *Task
+}
+
 // Notifier implements a system specific
 // notification. On Linux this libnotify.
 type Notifier interface {
 	Notify(string, string) error
+	// NotifyWithActions shows n with the given actions attached and
+	// returns a channel that receives the chosen ActionResult. The
+	// channel is closed once a result has been delivered or the
+	// notification expires without one.
+	NotifyWithActions(n Notification, actions []Action) (<-chan ActionResult, error)
 }
 
 // NoopNotifier does nothing
@@ -153,6 +214,12 @@ type NoopNotifier struct{}
 
 func (n NoopNotifier) Notify(string, string) error { return nil }
 
+func (n NoopNotifier) NotifyWithActions(_ Notification, _ []Action) (<-chan ActionResult, error) {
+	ch := make(chan ActionResult)
+	close(ch)
+	return ch, nil
+}
+
 // LibNotifier implements a Linux
 // notifier client.
 type LibNotifier struct {
@@ -185,6 +252,95 @@ func (ln LibNotifier) Notify(title, body string) error {
 	)
 }
 
+// NotifyWithActions bypasses libnotify.Client and talks to
+// org.freedesktop.Notifications directly so it can pass an Actions
+// array and watch for the resulting ActionInvoked signal.
+func (ln LibNotifier) NotifyWithActions(n Notification, actions []Action) (<-chan ActionResult, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	actionArgs := make([]string, 0, len(actions)*2)
+	for _, a := range actions {
+		actionArgs = append(actionArgs, a.ID, a.Label)
+	}
+
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(byte(n.Hints.Urgency)),
+	}
+	if n.Hints.Category != "" {
+		hints["category"] = dbus.MakeVariant(n.Hints.Category)
+	}
+	if n.Hints.Transient {
+		hints["transient"] = dbus.MakeVariant(true)
+	}
+	if n.Hints.Resident {
+		hints["resident"] = dbus.MakeVariant(true)
+	}
+
+	// DBus expire_timeout: -1 is the server default, 0 never expires.
+	timeout := int32(-1)
+	switch {
+	case n.Timeout < 0:
+		timeout = 0
+	case n.Timeout > 0:
+		timeout = int32(n.Timeout / time.Millisecond)
+	}
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.Call("org.freedesktop.Notifications.Notify", 0,
+		"pomo", uint32(0), ln.iconPath, n.Title, n.Body, actionArgs, hints, timeout)
+	if call.Err != nil {
+		return nil, call.Err
+	}
+	var id uint32
+	if err := call.Store(&id); err != nil {
+		return nil, err
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.Notifications"),
+		dbus.WithMatchMember("ActionInvoked"),
+	); err != nil {
+		return nil, err
+	}
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.Notifications"),
+		dbus.WithMatchMember("NotificationClosed"),
+	); err != nil {
+		return nil, err
+	}
+
+	signals := make(chan *dbus.Signal, 1)
+	conn.Signal(signals)
+
+	results := make(chan ActionResult, 1)
+	go func() {
+		defer close(results)
+		defer conn.RemoveSignal(signals)
+		for sig := range signals {
+			if len(sig.Body) != 2 {
+				continue
+			}
+			notifID, ok := sig.Body[0].(uint32)
+			if !ok || notifID != id {
+				continue
+			}
+			switch sig.Name {
+			case "org.freedesktop.Notifications.ActionInvoked":
+				actionID, _ := sig.Body[1].(string)
+				results <- ActionResult{ActionID: actionID}
+				return
+			case "org.freedesktop.Notifications.NotificationClosed":
+				return
+			}
+		}
+	}()
+
+	return results, nil
+}
+
 // xnotifier can push notifications to mac, linux and windows.
 type xnotifier struct {
 	*notificator.Notificator
@@ -210,6 +366,15 @@ func (n xnotifier) Notify(title, body string) error {
 	return n.Push(title, body, n.iconPath, notificator.UR_NORMAL)
 }
 
+// NotifyWithActions has no action support on macOS/Windows, so it
+// falls back to a plain Notify and returns a channel that closes
+// without a result.
+func (n xnotifier) NotifyWithActions(note Notification, _ []Action) (<-chan ActionResult, error) {
+	ch := make(chan ActionResult)
+	close(ch)
+	return ch, n.Notify(note.Title, note.Body)
+}
+
 type DarwinNotifier = xnotifier
 
 func NewDarwinNotifier(iconPath string) DarwinNotifier {